@@ -0,0 +1,307 @@
+// Package archive extracts common archive formats (zip, tar.gz, rar, jar)
+// so their contents can be scanned like any other file. Extraction always
+// goes through a TempDirAllocator that caps total decompressed bytes and
+// entry count, rejects symlinks, and rejects paths that would escape the
+// extraction root ("zip slip") — malicious payloads on scanned volumes are
+// routinely shipped inside archives, and a naive extractor is itself an
+// attack surface.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Format identifies a recognized archive container.
+type Format int
+
+const (
+	Unknown Format = iota
+	Zip
+	TarGz
+	Rar
+	Jar
+)
+
+// Entry is one file extracted from an archive.
+type Entry struct {
+	// Path is a synthetic path identifying the entry's location within the
+	// archive, e.g. "plugin.jar!/config.yml".
+	Path string
+	// ExtractedPath is where the entry's content was written on disk.
+	ExtractedPath string
+}
+
+// ErrToolNotInstalled is returned when extracting a format requires an
+// external tool (unrar/7z) that isn't on PATH.
+type ErrToolNotInstalled struct {
+	Tool string
+}
+
+func (e *ErrToolNotInstalled) Error() string {
+	return fmt.Sprintf("required tool %q is not installed", e.Tool)
+}
+
+var magicBytes = []struct {
+	prefix []byte
+	format Format
+}{
+	{[]byte("PK\x03\x04"), Zip},
+	{[]byte("PK\x05\x06"), Zip}, // empty zip
+	{[]byte{0x1f, 0x8b}, TarGz},
+	{[]byte("Rar!\x1a\x07"), Rar},
+}
+
+// DetectFormat identifies an archive by extension, falling back to magic
+// bytes when the extension is missing or unfamiliar. header only needs to
+// hold the first few bytes of the file.
+func DetectFormat(path string, header []byte) Format {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".jar"):
+		return Jar
+	case strings.HasSuffix(lower, ".zip"):
+		return Zip
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return TarGz
+	case strings.HasSuffix(lower, ".rar"), strings.HasSuffix(lower, ".cbr"):
+		return Rar
+	}
+
+	for _, m := range magicBytes {
+		if bytes.HasPrefix(header, m.prefix) {
+			return m.format
+		}
+	}
+	return Unknown
+}
+
+// Extract unpacks path into a fresh directory managed by alloc and returns
+// the resulting entries. The caller must call the returned cleanup func
+// once it's done reading the entries.
+func Extract(path string, format Format, alloc *TempDirAllocator) ([]Entry, func(), error) {
+	a, err := alloc.new(filepath.Base(path))
+	if err != nil {
+		return nil, func() {}, err
+	}
+	cleanup := func() { a.close() }
+
+	var entries []Entry
+	switch format {
+	case Zip, Jar:
+		entries, err = extractZip(path, a)
+	case TarGz:
+		entries, err = extractTarGz(path, a)
+	case Rar:
+		entries, err = extractRar(path, a)
+	default:
+		err = fmt.Errorf("unsupported archive format for %s", path)
+	}
+
+	if err != nil {
+		cleanup()
+		return nil, func() {}, err
+	}
+	return entries, cleanup, nil
+}
+
+func syntheticPath(archivePath, entryName string) string {
+	return archivePath + "!/" + entryName
+}
+
+func extractZip(path string, a *allocation) ([]Entry, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening zip %s: %w", path, err)
+	}
+	defer zr.Close()
+
+	var entries []Entry
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || f.FileInfo().Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("reading zip entry %s: %w", f.Name, err)
+		}
+		dest, err := a.writeEntry(f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, Entry{Path: syntheticPath(path, f.Name), ExtractedPath: dest})
+	}
+	return entries, nil
+}
+
+func extractTarGz(path string, a *allocation) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("opening gzip %s: %w", path, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+
+	var entries []Entry
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading tar entry in %s: %w", path, err)
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue // skip dirs, symlinks, hardlinks, devices, ...
+		}
+
+		dest, err := a.writeEntry(hdr.Name, tr)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, Entry{Path: syntheticPath(path, hdr.Name), ExtractedPath: dest})
+	}
+	return entries, nil
+}
+
+// rarTools is tried in order; the first one found on PATH is used.
+var rarTools = []string{"unrar", "7z"}
+
+func extractRar(path string, a *allocation) ([]Entry, error) {
+	tool, err := findRarTool()
+	if err != nil {
+		return nil, err
+	}
+
+	var cmd *exec.Cmd
+	switch filepath.Base(tool) {
+	case "unrar":
+		cmd = exec.Command(tool, "x", "-y", "-o+", path, a.dir+string(os.PathSeparator))
+	case "7z":
+		cmd = exec.Command(tool, "x", "-y", "-o"+a.dir, path)
+	default:
+		return nil, &ErrToolNotInstalled{Tool: rarTools[0]}
+	}
+
+	if err := runBounded(cmd, a); err != nil {
+		return nil, fmt.Errorf("extracting rar %s with %s: %w", path, tool, err)
+	}
+
+	var entries []Entry
+	walkErr := filepath.Walk(a.dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil // reject symlink entries, same as extractZip/extractTarGz
+		}
+		rel, err := filepath.Rel(a.dir, p)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, Entry{Path: syntheticPath(path, filepath.ToSlash(rel)), ExtractedPath: p})
+		return nil
+	})
+	if walkErr != nil {
+		return nil, fmt.Errorf("walking extracted rar %s: %w", path, walkErr)
+	}
+	if len(entries) > a.limits.MaxEntries {
+		return nil, fmt.Errorf("archive %s exceeds max entry count (%d)", path, a.limits.MaxEntries)
+	}
+	return entries, nil
+}
+
+// runBounded runs cmd (an unrar/7z extraction writing into a.dir) while
+// polling a.dir's size and entry count against a.limits, killing the
+// process as soon as either is exceeded. Neither unrar nor 7z offers a
+// portable "abort past N decompressed bytes" flag, so this is how the
+// zip-bomb guard that TempDirAllocator enforces for zip/tar.gz gets applied
+// to the shelled-out rar path too.
+func runBounded(cmd *exec.Cmd, a *allocation) error {
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				return fmt.Errorf("%w: %s", err, output.String())
+			}
+			// The tool may finish between polls, so check the final size too.
+			if size, _, err := dirStats(a.dir); err == nil && size > a.limits.MaxBytes {
+				return fmt.Errorf("extraction into %s exceeded max decompressed size (%d bytes)", a.dir, a.limits.MaxBytes)
+			}
+			return nil
+		case <-ticker.C:
+			size, count, err := dirStats(a.dir)
+			if err != nil {
+				continue
+			}
+			if size > a.limits.MaxBytes || count > a.limits.MaxEntries {
+				_ = cmd.Process.Kill()
+				<-done
+				return fmt.Errorf("extraction into %s exceeded max decompressed size (%d bytes) or entry count (%d)", a.dir, a.limits.MaxBytes, a.limits.MaxEntries)
+			}
+		}
+	}
+}
+
+// dirStats returns the total file size and file count currently written
+// under dir.
+func dirStats(dir string) (size int64, count int, err error) {
+	err = filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+			count++
+		}
+		return nil
+	})
+	return size, count, err
+}
+
+func findRarTool() (string, error) {
+	for _, tool := range rarTools {
+		if p, err := exec.LookPath(tool); err == nil {
+			return p, nil
+		}
+	}
+	return "", &ErrToolNotInstalled{Tool: rarTools[0]}
+}
@@ -0,0 +1,161 @@
+package archive
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// buildZip writes a zip file under t.TempDir() containing the given entries
+// and returns its path.
+func buildZip(t *testing.T, entries map[string]string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("creating zip: %v", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("adding entry %q: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("writing entry %q: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("closing zip: %v", err)
+	}
+	return path
+}
+
+// buildZipWithSymlink writes a zip file containing a single symlink entry
+// pointing at target, plus one regular file, and returns its path.
+func buildZipWithSymlink(t *testing.T, linkName, target string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "symlink.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("creating zip: %v", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+
+	fh := &zip.FileHeader{Name: linkName}
+	fh.SetMode(os.ModeSymlink | 0o777)
+	w, err := zw.CreateHeader(fh)
+	if err != nil {
+		t.Fatalf("adding symlink entry: %v", err)
+	}
+	if _, err := w.Write([]byte(target)); err != nil {
+		t.Fatalf("writing symlink target: %v", err)
+	}
+
+	regular, err := zw.Create("real.txt")
+	if err != nil {
+		t.Fatalf("adding regular entry: %v", err)
+	}
+	if _, err := regular.Write([]byte("hello")); err != nil {
+		t.Fatalf("writing regular entry: %v", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		t.Fatalf("closing zip: %v", err)
+	}
+	return path
+}
+
+func TestExtractZip(t *testing.T) {
+	path := buildZip(t, map[string]string{
+		"a.txt":     "hello",
+		"dir/b.txt": "world",
+	})
+
+	alloc := NewTempDirAllocator()
+	entries, cleanup, err := Extract(path, Zip, alloc)
+	defer cleanup()
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(entries), entries)
+	}
+}
+
+func TestExtractZipRejectsSymlinks(t *testing.T) {
+	path := buildZipWithSymlink(t, "evil-link", "/etc/passwd")
+
+	alloc := NewTempDirAllocator()
+	entries, cleanup, err := Extract(path, Zip, alloc)
+	defer cleanup()
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+
+	for _, e := range entries {
+		if e.Path == syntheticPath(path, "evil-link") {
+			t.Fatalf("symlink entry %q was extracted, want it skipped", e.Path)
+		}
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1 (only the regular file): %+v", len(entries), entries)
+	}
+}
+
+func TestExtractZipEnforcesMaxBytes(t *testing.T) {
+	path := buildZip(t, map[string]string{
+		"big.txt": "this content is way more than ten bytes long",
+	})
+
+	alloc := &TempDirAllocator{MaxBytes: 10, MaxEntries: DefaultMaxEntries}
+	_, cleanup, err := Extract(path, Zip, alloc)
+	defer cleanup()
+	if err == nil {
+		t.Fatal("Extract: expected an error for an archive exceeding MaxBytes, got nil")
+	}
+}
+
+func TestExtractZipEnforcesMaxEntries(t *testing.T) {
+	path := buildZip(t, map[string]string{
+		"a.txt": "1",
+		"b.txt": "2",
+		"c.txt": "3",
+	})
+
+	alloc := &TempDirAllocator{MaxBytes: DefaultMaxBytes, MaxEntries: 1}
+	_, cleanup, err := Extract(path, Zip, alloc)
+	defer cleanup()
+	if err == nil {
+		t.Fatal("Extract: expected an error for an archive exceeding MaxEntries, got nil")
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		path   string
+		header []byte
+		want   Format
+	}{
+		{path: "plugin.jar", header: nil, want: Jar},
+		{path: "archive.zip", header: nil, want: Zip},
+		{path: "archive.tar.gz", header: nil, want: TarGz},
+		{path: "archive.rar", header: nil, want: Rar},
+		{path: "unknown.bin", header: []byte("PK\x03\x04"), want: Zip},
+		{path: "unknown.bin", header: []byte{0x1f, 0x8b}, want: TarGz},
+		{path: "unknown.bin", header: []byte("not an archive"), want: Unknown},
+	}
+
+	for _, tt := range tests {
+		if got := DetectFormat(tt.path, tt.header); got != tt.want {
+			t.Errorf("DetectFormat(%q, %v) = %v, want %v", tt.path, tt.header, got, tt.want)
+		}
+	}
+}
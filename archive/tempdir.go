@@ -0,0 +1,94 @@
+package archive
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Default guards against zip-bomb style archives: no single archive may
+// inflate past this many decompressed bytes or this many entries.
+const (
+	DefaultMaxBytes   int64 = 500 * 1024 * 1024 // 500 MB
+	DefaultMaxEntries       = 20000
+)
+
+// TempDirAllocator hands out bounded scratch directories for archive
+// extraction. Every directory it creates enforces the same total byte and
+// entry count caps, independent of which archive format is being unpacked.
+type TempDirAllocator struct {
+	MaxBytes   int64
+	MaxEntries int
+}
+
+// NewTempDirAllocator returns an allocator using gscan's default caps.
+func NewTempDirAllocator() *TempDirAllocator {
+	return &TempDirAllocator{MaxBytes: DefaultMaxBytes, MaxEntries: DefaultMaxEntries}
+}
+
+func (t *TempDirAllocator) new(prefix string) (*allocation, error) {
+	dir, err := os.MkdirTemp("", "gscan-"+prefix+"-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating extraction dir: %w", err)
+	}
+	return &allocation{dir: dir, limits: t}, nil
+}
+
+// allocation tracks the bytes and entries written into one extraction
+// directory so limits are enforced across an archive's entire entry list,
+// not just per-entry.
+type allocation struct {
+	dir          string
+	limits       *TempDirAllocator
+	bytesWritten int64
+	entryCount   int
+}
+
+func (a *allocation) close() error {
+	return os.RemoveAll(a.dir)
+}
+
+// writeEntry streams r to a new file under the allocation's directory,
+// rejecting paths that would escape it and stopping as soon as the
+// allocator's byte or entry caps are exceeded.
+func (a *allocation) writeEntry(name string, r io.Reader) (string, error) {
+	a.entryCount++
+	if a.entryCount > a.limits.MaxEntries {
+		return "", fmt.Errorf("archive exceeds max entry count (%d)", a.limits.MaxEntries)
+	}
+
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("entry %q escapes the extraction root", name)
+	}
+
+	dest := filepath.Join(a.dir, clean)
+	if !strings.HasPrefix(dest, a.dir+string(os.PathSeparator)) {
+		return "", fmt.Errorf("entry %q escapes the extraction root", name)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return "", fmt.Errorf("creating directory for entry %q: %w", name, err)
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return "", fmt.Errorf("creating entry %q: %w", name, err)
+	}
+	defer f.Close()
+
+	remaining := a.limits.MaxBytes - a.bytesWritten
+	n, err := io.Copy(f, io.LimitReader(r, remaining+1))
+	if err != nil {
+		return "", fmt.Errorf("writing entry %q: %w", name, err)
+	}
+	a.bytesWritten += n
+
+	if a.bytesWritten > a.limits.MaxBytes {
+		return "", fmt.Errorf("archive exceeds max decompressed size (%d bytes)", a.limits.MaxBytes)
+	}
+
+	return dest, nil
+}
@@ -0,0 +1,154 @@
+// Package cache maintains a persistent, content-hash-keyed record of scan
+// results so that re-running gscan over a volume that hasn't changed since
+// the last scan doesn't have to re-open, re-hash, and re-run every rule
+// against every file again.
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CachedFinding is the subset of a rules.Finding worth persisting: enough to
+// reconstruct the finding exactly on a cache hit without re-reading the
+// file. Severity and description are deliberately left out since they're
+// looked up fresh from the rule set, which a cache hit already guarantees
+// matches.
+type CachedFinding struct {
+	RuleID  string `json:"rule_id"`
+	Line    int    `json:"line"`
+	Snippet string `json:"snippet"`
+}
+
+// Entry is the last known scan result for one file.
+type Entry struct {
+	Path         string          `json:"path"`
+	ModTime      int64           `json:"mod_time"`
+	Size         int64           `json:"size"`
+	SHA256       string          `json:"sha256"`
+	RulesVersion string          `json:"rules_version"`
+	Findings     []CachedFinding `json:"findings"`
+	Language     string          `json:"language"`
+}
+
+// Cache is a JSON-file-backed store of Entry, keyed by path. It is safe for
+// concurrent use by multiple scanning goroutines.
+type Cache struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]Entry
+	dirty   bool
+}
+
+// Open loads the cache from path, returning an empty Cache if the file
+// doesn't exist yet (e.g. on a host's first scan).
+func Open(path string) (*Cache, error) {
+	c := &Cache{path: path, entries: make(map[string]Entry)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading cache %s: %w", path, err)
+	}
+
+	var list []Entry
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("parsing cache %s: %w", path, err)
+	}
+	for _, e := range list {
+		c.entries[e.Path] = e
+	}
+	return c, nil
+}
+
+// LookupStat is the cheap pre-read check: if path's mtime and size match
+// what was recorded for the current rule set, the caller can skip opening
+// the file entirely and reuse the cached result.
+func (c *Cache) LookupStat(path string, modTime, size int64, rulesVersion string) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[path]
+	if !ok || e.RulesVersion != rulesVersion || e.ModTime != modTime || e.Size != size {
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// LookupHash is consulted once content has already been read and hashed
+// (because LookupStat missed). It catches the case where a file was
+// touched but its content didn't actually change, so the caller can still
+// skip re-running rules against it.
+func (c *Cache) LookupHash(path, sha256Hex, rulesVersion string) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[path]
+	if !ok || e.RulesVersion != rulesVersion || e.SHA256 != sha256Hex {
+		return Entry{}, false
+	}
+	return e, true
+}
+
+// Store records (or replaces) the scan result for e.Path.
+func (c *Cache) Store(e Entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[e.Path] = e
+	c.dirty = true
+}
+
+// Prune drops entries for files that no longer exist on disk and returns
+// how many were removed.
+func (c *Cache) Prune() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dropped := 0
+	for path := range c.entries {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			delete(c.entries, path)
+			dropped++
+			c.dirty = true
+		}
+	}
+	return dropped
+}
+
+// Save writes the cache back to disk if anything changed since Open.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.dirty {
+		return nil
+	}
+
+	list := make([]Entry, 0, len(c.entries))
+	for _, e := range c.entries {
+		list = append(list, e)
+	}
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	if err := os.WriteFile(c.path, data, 0o644); err != nil {
+		return fmt.Errorf("writing cache %s: %w", c.path, err)
+	}
+	c.dirty = false
+	return nil
+}
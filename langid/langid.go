@@ -0,0 +1,285 @@
+// Package langid classifies files by programming language using the same
+// combination of signals as linguist/enry: filename and extension
+// heuristics, shebang parsing for extension-less scripts, and a small
+// keyword tiebreaker for extensions that are ambiguous on their own (.h,
+// .pl, .ts). It also tags files as vendored, documentation, or
+// configuration so callers can exclude them from "real code" percentages
+// without hard-coding directory names at every call site.
+package langid
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+// Classification is the result of classifying a single file.
+type Classification struct {
+	// Language is the detected language name, e.g. "JavaScript", "Python",
+	// "Markdown". "Binary" and "Unknown" are used when no signal matches.
+	Language string
+	// Vendored is true when the file lives under a directory that is
+	// conventionally third-party or generated (node_modules, vendor, ...).
+	Vendored bool
+	// Documentation is true for prose files such as README/CHANGELOG/*.md.
+	Documentation bool
+	// Configuration is true for structured config formats (YAML/JSON/TOML/...).
+	Configuration bool
+}
+
+const (
+	Unknown = "Unknown"
+	Binary  = "Binary"
+)
+
+// vendoredDirs lists directory names that are conventionally vendored,
+// generated, or otherwise not "the project's own code". This replaces the
+// hard-coded node_modules/plugins/assets skip checks that used to live in
+// the walker.
+var vendoredDirs = map[string]bool{
+	"node_modules":     true,
+	"bower_components": true,
+	"vendor":           true,
+	"third_party":      true,
+	"plugins":          true,
+	"assets":           true,
+	"dist":             true,
+	"build":            true,
+}
+
+// IsVendoredDir reports whether name (a single path segment, not a full
+// path) names a conventionally vendored directory.
+func IsVendoredDir(name string) bool {
+	return vendoredDirs[name]
+}
+
+// IsDotfile reports whether name is a dotfile/dot-directory.
+func IsDotfile(name string) bool {
+	return len(name) > 1 && strings.HasPrefix(name, ".")
+}
+
+// IsVendoredPath reports whether any segment of path falls under a
+// vendored directory per IsVendoredDir.
+func IsVendoredPath(path string) bool {
+	for _, seg := range strings.Split(filepath.ToSlash(path), "/") {
+		if IsVendoredDir(seg) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDocumentationPath reports whether path names a documentation file
+// (README, CHANGELOG, *.md, ...) based on its name and extension alone.
+func IsDocumentationPath(path string) bool {
+	base := filepath.Base(path)
+	ext := strings.ToLower(filepath.Ext(path))
+	return docFilenames[base] || docExts[ext]
+}
+
+// IsConfigurationPath reports whether path names a structured config file
+// (YAML/JSON/TOML/...) based on its extension alone.
+func IsConfigurationPath(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return configExts[ext]
+}
+
+var extLanguages = map[string]string{
+	".js":    "JavaScript",
+	".mjs":   "JavaScript",
+	".cjs":   "JavaScript",
+	".jsx":   "JavaScript",
+	".py":    "Python",
+	".pyw":   "Python",
+	".go":    "Go",
+	".rb":    "Ruby",
+	".java":  "Java",
+	".kt":    "Kotlin",
+	".c":     "C",
+	".cc":    "C++",
+	".cpp":   "C++",
+	".cs":    "C#",
+	".php":   "PHP",
+	".rs":    "Rust",
+	".sh":    "Shell",
+	".bash":  "Shell",
+	".lua":   "Lua",
+	".sql":   "SQL",
+	".swift": "Swift",
+}
+
+// ambiguousExts maps an extension to the candidate languages it might mean;
+// Detect disambiguates these with a keyword scan of the file's content.
+var ambiguousExts = map[string][]string{
+	".h":  {"C", "C++", "Objective-C"},
+	".pl": {"Perl", "Prolog"},
+	".ts": {"TypeScript", "XML"}, // also used for some translation/data formats
+}
+
+var filenameLanguages = map[string]string{
+	"Dockerfile": "Dockerfile",
+	"Makefile":   "Makefile",
+	"Rakefile":   "Ruby",
+	"Gemfile":    "Ruby",
+}
+
+var docFilenames = map[string]bool{
+	"README": true, "README.md": true, "README.rst": true, "README.txt": true,
+	"CHANGELOG": true, "CHANGELOG.md": true,
+	"LICENSE": true, "LICENSE.md": true, "LICENSE.txt": true,
+	"CONTRIBUTING.md": true, "NOTICE": true,
+}
+
+var docExts = map[string]bool{
+	".md": true, ".rst": true, ".adoc": true, ".txt": true,
+}
+
+var configExts = map[string]bool{
+	".yaml": true, ".yml": true, ".json": true, ".toml": true,
+	".ini": true, ".cfg": true, ".conf": true, ".env": true,
+}
+
+var shebangLanguages = map[string]string{
+	"python":  "Python",
+	"python2": "Python",
+	"python3": "Python",
+	"node":    "JavaScript",
+	"bash":    "Shell",
+	"sh":      "Shell",
+	"ruby":    "Ruby",
+	"perl":    "Perl",
+	"lua":     "Lua",
+}
+
+// Detect classifies a file from its path and a (possibly truncated) content
+// sample. Passing a nil/empty sample still yields a filename/extension-based
+// classification; passing a real sample additionally enables shebang parsing
+// and ambiguous-extension disambiguation.
+func Detect(path string, sample []byte) Classification {
+	base := filepath.Base(path)
+	ext := strings.ToLower(filepath.Ext(path))
+
+	c := Classification{
+		Vendored:      IsVendoredPath(path),
+		Documentation: IsDocumentationPath(path),
+		Configuration: IsConfigurationPath(path),
+	}
+
+	if lang, ok := filenameLanguages[base]; ok {
+		c.Language = lang
+		return c
+	}
+
+	if looksBinary(sample) {
+		c.Language = Binary
+		return c
+	}
+
+	if candidates, ok := ambiguousExts[ext]; ok {
+		c.Language = disambiguate(candidates, sample)
+		return c
+	}
+
+	if lang, ok := extLanguages[ext]; ok {
+		c.Language = lang
+		return c
+	}
+
+	if ext == "" {
+		if lang, ok := detectShebang(sample); ok {
+			c.Language = lang
+			return c
+		}
+	}
+
+	if c.Documentation {
+		c.Language = "Markdown"
+		return c
+	}
+	if c.Configuration {
+		c.Language = "Config"
+		return c
+	}
+
+	c.Language = Unknown
+	return c
+}
+
+// looksBinary applies the same heuristic as most "is this text" checks: a
+// NUL byte in the first chunk of the file means it's not source code.
+func looksBinary(sample []byte) bool {
+	n := len(sample)
+	if n > 512 {
+		n = 512
+	}
+	return bytes.IndexByte(sample[:n], 0) >= 0
+}
+
+func detectShebang(sample []byte) (string, bool) {
+	nl := bytes.IndexByte(sample, '\n')
+	if nl < 0 {
+		nl = len(sample)
+	}
+	line := string(sample[:nl])
+	if !strings.HasPrefix(line, "#!") {
+		return "", false
+	}
+
+	interpreter := filepath.Base(strings.TrimSpace(line[2:]))
+	fields := strings.Fields(interpreter)
+	if len(fields) == 0 {
+		return "", false
+	}
+	name := fields[0]
+	if name == "env" && len(fields) > 1 {
+		name = fields[1]
+	}
+
+	lang, ok := shebangLanguages[name]
+	return lang, ok
+}
+
+// disambiguate picks between a shortlist of candidate languages for an
+// ambiguous extension using a handful of keyword signals. It's a tiebreaker,
+// not a parser: good enough to stop .h files from all being lumped in with
+// C, or .pl Prolog scripts from being counted as Perl.
+func disambiguate(candidates []string, sample []byte) string {
+	s := string(sample)
+
+	has := func(needles ...string) bool {
+		for _, n := range needles {
+			if strings.Contains(s, n) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, lang := range candidates {
+		switch lang {
+		case "Objective-C":
+			if has("@interface", "@implementation", "#import") {
+				return lang
+			}
+		case "C++":
+			if has("class ", "namespace ", "std::", "template<") {
+				return lang
+			}
+		case "Prolog":
+			if has(":-", "-->") {
+				return lang
+			}
+		case "TypeScript":
+			if has("interface ", ": string", ": number", "import type") {
+				return lang
+			}
+		case "XML":
+			if has("<?xml", "<translationbundle") {
+				return lang
+			}
+		}
+	}
+
+	// No signal matched: fall back to the first (most common) candidate.
+	return candidates[0]
+}
@@ -0,0 +1,162 @@
+package langid
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		sample string
+		want   string
+	}{
+		{
+			name: "extension",
+			path: "main.go",
+			want: "Go",
+		},
+		{
+			name: "filename override beats extension",
+			path: "Dockerfile",
+			want: "Dockerfile",
+		},
+		{
+			name:   "shebang python",
+			path:   "build",
+			sample: "#!/usr/bin/python3\nprint('hi')\n",
+			want:   "Python",
+		},
+		{
+			name:   "shebang env indirection",
+			path:   "run",
+			sample: "#!/usr/bin/env bash\necho hi\n",
+			want:   "Shell",
+		},
+		{
+			name:   "no shebang and no extension is unknown",
+			path:   "README_NOTES",
+			sample: "just some notes\n",
+			want:   "Unknown",
+		},
+		{
+			name:   "ambiguous .h disambiguates to Objective-C",
+			path:   "widget.h",
+			sample: "@interface Widget : NSObject\n@end\n",
+			want:   "Objective-C",
+		},
+		{
+			name:   "ambiguous .h disambiguates to C++",
+			path:   "widget.h",
+			sample: "namespace widget {\nclass Widget {};\n}\n",
+			want:   "C++",
+		},
+		{
+			name:   "ambiguous .h falls back to C with no signal",
+			path:   "widget.h",
+			sample: "int widget(void);\n",
+			want:   "C",
+		},
+		{
+			name:   "ambiguous .pl disambiguates to Prolog",
+			path:   "rules.pl",
+			sample: "likes(X, Y) :- food(Y).\n",
+			want:   "Prolog",
+		},
+		{
+			name:   "ambiguous .pl falls back to Perl with no signal",
+			path:   "script.pl",
+			sample: "print \"hi\\n\";\n",
+			want:   "Perl",
+		},
+		{
+			name:   "ambiguous .ts disambiguates to XML",
+			path:   "strings.ts",
+			sample: "<?xml version=\"1.0\"?>\n<translationbundle/>\n",
+			want:   "XML",
+		},
+		{
+			name:   "ambiguous .ts falls back to TypeScript with no signal",
+			path:   "widget.ts",
+			sample: "export const x = 1\n",
+			want:   "TypeScript",
+		},
+		{
+			name:   "NUL byte marks content binary regardless of extension",
+			path:   "payload.js",
+			sample: "MZ\x00\x00garbage",
+			want:   Binary,
+		},
+		{
+			name: "markdown extension",
+			path: "CHANGELOG.md",
+			want: "Markdown",
+		},
+		{
+			name: "config extension",
+			path: "settings.yaml",
+			want: "Config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Detect(tt.path, []byte(tt.sample))
+			if got.Language != tt.want {
+				t.Fatalf("Detect(%q).Language = %q, want %q", tt.path, got.Language, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectVendoredDocConfigTags(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		vendored      bool
+		documentation bool
+		configuration bool
+	}{
+		{
+			name:     "vendored directory",
+			path:     "node_modules/left-pad/index.js",
+			vendored: true,
+		},
+		{
+			name:          "documentation filename",
+			path:          "README.md",
+			documentation: true,
+		},
+		{
+			name:          "configuration extension",
+			path:          "config/app.yaml",
+			configuration: true,
+		},
+		{
+			name: "ordinary source file",
+			path: "internal/server/handler.go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Detect(tt.path, nil)
+			if got.Vendored != tt.vendored {
+				t.Errorf("Vendored = %v, want %v", got.Vendored, tt.vendored)
+			}
+			if got.Documentation != tt.documentation {
+				t.Errorf("Documentation = %v, want %v", got.Documentation, tt.documentation)
+			}
+			if got.Configuration != tt.configuration {
+				t.Errorf("Configuration = %v, want %v", got.Configuration, tt.configuration)
+			}
+		})
+	}
+}
+
+func TestIsVendoredDir(t *testing.T) {
+	if !IsVendoredDir("node_modules") {
+		t.Error("IsVendoredDir(\"node_modules\") = false, want true")
+	}
+	if IsVendoredDir("src") {
+		t.Error("IsVendoredDir(\"src\") = true, want false")
+	}
+}
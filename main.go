@@ -1,204 +1,127 @@
 package main
 
 import (
-    "fmt"
-    "io/ioutil"
-    "os"
-    "path/filepath"
-    "strings"
-    "unicode"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/sryden-inc/gscan-go-rewrite/cache"
+	"github.com/sryden-inc/gscan-go-rewrite/report"
+	"github.com/sryden-inc/gscan-go-rewrite/rules"
+	"github.com/sryden-inc/gscan-go-rewrite/scan"
 )
 
-const maxFileSize = 1024 * 1024 * 10 // 10 MB
-const maxDepth = 3                   // maximum depth of directory traversal
+const defaultCacheFile = "/var/lib/gscan/scan-cache.json"
 
 func main() {
-    volumesDir := "/var/lib/pterodactyl/volumes/"
-    volumes, err := ioutil.ReadDir(volumesDir)
-    if err != nil {
-        fmt.Println("Error reading volumes directory:", err)
-        return
-    }
-
-    var allFileFlags map[string][]string
-
-    for _, volume := range volumes {
-        if volume.IsDir() {
-            volumePath := filepath.Join(volumesDir, volume.Name())
-            languagePercentages, fileFlags := analyzeFiles(volumePath, 1)
-            if len(fileFlags) > 0 {
-                printLanguagePercentages(volumePath, languagePercentages, fileFlags)
-                allFileFlags = mergeMaps(allFileFlags, fileFlags)
-            }
-        }
-    }
-
-    printFlagSummary(allFileFlags)
+	if len(os.Args) > 1 && os.Args[1] == "cache" {
+		if err := runCacheCommand(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	format := flag.String("format", "text", "output format: text, json, or sarif")
+	outFile := flag.String("o", "", "write the report to this file instead of stdout")
+	volumesDir := flag.String("volumes-dir", "/var/lib/pterodactyl/volumes/", "directory containing volumes to scan")
+	includeVendored := flag.Bool("include-vendored", false, "count vendored files (node_modules, plugins, assets, ...) toward language percentages")
+	cacheFile := flag.String("cache-file", defaultCacheFile, "path to the persistent scan cache")
+	noCache := flag.Bool("no-cache", false, "ignore and don't update the scan cache")
+	flag.Parse()
+
+	if err := run(*volumesDir, report.Format(*format), *outFile, *includeVendored, *cacheFile, *noCache); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
 }
 
-func analyzeFiles(dirPath string, depth int) (map[string]float64, map[string][]string) {
-    if depth > maxDepth {
-        return nil, nil
-    }
-
-    languageCounts := make(map[string]int)
-    totalFiles := 0
-    fileFlags := make(map[string][]string)
-
-    err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
-        if err != nil {
-            return err
-        }
-
-        // Ignore directories that start with "." or "?"
-        if info.IsDir() && (strings.HasPrefix(info.Name(), ".") || strings.HasPrefix(info.Name(), "?")) {
-            return filepath.SkipDir
-        }
-
-        if info.IsDir() && (info.Name() == "node_modules" || strings.HasPrefix(info.Name(), "node_modules"+string(os.PathSeparator))) {
-            return filepath.SkipDir // skip node_modules directory and its subdirectories
-        }
-
-        if info.IsDir() && (info.Name() == "plugins" || strings.HasPrefix(info.Name(), "plugins"+string(os.PathSeparator))) {
-            return filepath.SkipDir // skip node_modules directory and its subdirectories
-        }
-
-        if info.IsDir() && (info.Name() == "assets" || strings.HasPrefix(info.Name(), "assets"+string(os.PathSeparator))) {
-            return filepath.SkipDir // skip node_modules directory and its subdirectories
-        }
-
-        if !info.IsDir() {
-            ext := filepath.Ext(path)
-            if ext == ".js" || ext == ".py" {
-                content, err := readFileWithLimit(path, maxFileSize)
-                if err != nil {
-                    fmt.Printf("Error reading file %s: %v\n", path, err)
-                    return nil
-                }
-
-                flags := checkFlags(string(content), path)
-                if len(flags) > 0 {
-                    fileFlags[path] = flags
-                }
-            }
-
-            languageCounts[ext]++
-            totalFiles++
-        }
-
-        return nil
-    })
-
-    if err != nil {
-        fmt.Printf("Error walking directory %s: %v\n", dirPath, err)
-        return nil, nil
-    }
-
-    languagePercentages := make(map[string]float64)
-    for ext, count := range languageCounts {
-        percentage := float64(count) / float64(totalFiles) * 100.0
-        languagePercentages[ext] = percentage
-    }
-
-    for subDir, _ := range fileFlags {
-        subDirPath := filepath.Dir(subDir)
-        if subDirPath != dirPath {
-            subdirLanguagePercentages, subdirFileFlags := analyzeFiles(subDirPath, depth+1)
-            for ext, percentage := range subdirLanguagePercentages {
-                languagePercentages[ext] += percentage
-            }
-            for path, flags := range subdirFileFlags {
-                fileFlags[path] = flags
-            }
-        }
-    }
-
-    return languagePercentages, fileFlags
+func run(volumesDir string, format report.Format, outFile string, includeVendored bool, cacheFile string, noCache bool) error {
+	volumes, err := ioutil.ReadDir(volumesDir)
+	if err != nil {
+		return fmt.Errorf("reading volumes directory: %w", err)
+	}
+
+	scanner, err := rules.NewDefaultScanner()
+	if err != nil {
+		return fmt.Errorf("loading default rules: %w", err)
+	}
+
+	var scanCache *cache.Cache
+	if !noCache {
+		scanCache, err = cache.Open(cacheFile)
+		if err != nil {
+			return fmt.Errorf("opening scan cache: %w", err)
+		}
+	}
+
+	var volumePaths []string
+	var results []scan.Result
+
+	for _, volume := range volumes {
+		if !volume.IsDir() {
+			continue
+		}
+
+		volumePath := filepath.Join(volumesDir, volume.Name())
+		result, err := scan.Walk(volumePath, scan.Options{
+			Scanner:         scanner,
+			IncludeVendored: includeVendored,
+			Cache:           scanCache,
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error scanning volume:", err)
+			continue
+		}
+
+		volumePaths = append(volumePaths, volumePath)
+		results = append(results, result)
+	}
+
+	if scanCache != nil {
+		if err := scanCache.Save(); err != nil {
+			fmt.Fprintln(os.Stderr, "Error saving scan cache:", err)
+		}
+	}
+
+	out := os.Stdout
+	if outFile != "" {
+		f, err := os.Create(outFile)
+		if err != nil {
+			return fmt.Errorf("creating output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	return report.Render(out, format, report.New(volumePaths, results))
 }
 
-func readFileWithLimit(path string, limit int64) ([]byte, error) {
-    file, err := os.Open(path)
-    if err != nil {
-        return nil, err
-    }
-    defer file.Close()
-
-    fi, err := file.Stat()
-    if err != nil {
-        return nil, err
-    }
-
-    if fi.Size() > limit {
-        return nil, fmt.Errorf("file %s is too large (size: %d bytes, limit: %d bytes)", path, fi.Size(), limit)
-    }
-
-    return ioutil.ReadAll(file)
-}
-
-func checkFlags(content string, path string) []string {
-    flags := []string{}
-    if strings.Contains(content, "nezha") {
-        flags = append(flags, "Nezha was detected")
-    }
-    if containsChinese(content) {
-        flags = append(flags, "Contains Chinese characters")
-    }
-    if filepath.Ext(path) == ".sh" {
-        flags = append(flags, "File ends with .sh")
-    }
-    if strings.Contains(content, "argo") || strings.Contains(content, "cloudflare") {
-        flags = append(flags, "File contains 'argo' or 'cloudflare'")
-    }
-    return flags
-}
-
-func containsChinese(s string) bool {
-    for _, r := range s {
-        if unicode.Is(unicode.Scripts["Han"], r) {
-            return true
-        }
-    }
-    return false
-}
-
-func printLanguagePercentages(dirPath string, languagePercentages map[string]float64, fileFlags map[string][]string) {
-    fmt.Printf("Directory: %s\n\nLanguages:\n", dirPath)
-    for ext, percentage := range languagePercentages {
-        language := strings.TrimPrefix(ext, ".")
-        fmt.Printf("* %.0f%% %s\n", percentage, language)
-    }
-
-    fmt.Println("\nFlags found in files:")
-    for path, flags := range fileFlags {
-        fmt.Printf("%s:\n", path)
-        for _, flag := range flags {
-            fmt.Printf("- %s\n", flag)
-        }
-    }
-
-    fmt.Println()
-}
-
-func mergeMaps(dest, src map[string][]string) map[string][]string {
-    if dest == nil {
-        dest = make(map[string][]string)
-    }
-    for key, value := range src {
-        dest[key] = value
-    }
-    return dest
-}
-
-func printFlagSummary(fileFlags map[string][]string) {
-    if len(fileFlags) == 0 {
-        fmt.Println("No flags found in any volume.")
-        return
-    }
-    fmt.Println("Summary of flags found in all volumes:")
-    for path, flags := range fileFlags {
-        fmt.Printf("%s:\n", path)
-        for _, flag := range flags {
-            fmt.Printf("- %s\n", flag)
-        }
-    }
+// runCacheCommand implements the `gscan cache prune` subcommand.
+func runCacheCommand(args []string) error {
+	fs := flag.NewFlagSet("cache", flag.ExitOnError)
+	cacheFile := fs.String("cache-file", defaultCacheFile, "path to the persistent scan cache")
+	if len(args) == 0 || args[0] != "prune" {
+		return fmt.Errorf("usage: gscan cache prune [-cache-file path]")
+	}
+	fs.Parse(args[1:])
+
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: gscan cache prune [-cache-file path]")
+	}
+
+	c, err := cache.Open(*cacheFile)
+	if err != nil {
+		return fmt.Errorf("opening scan cache: %w", err)
+	}
+
+	dropped := c.Prune()
+	if err := c.Save(); err != nil {
+		return fmt.Errorf("saving scan cache: %w", err)
+	}
+
+	fmt.Printf("Pruned %d stale entries from %s\n", dropped, *cacheFile)
+	return nil
 }
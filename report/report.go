@@ -0,0 +1,144 @@
+// Package report renders scan results to the sinks gscan supports: plain
+// text for a terminal, a machine-readable JSON schema, and SARIF 2.1.0 for
+// ingestion by GitHub code scanning and similar dashboards.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/sryden-inc/gscan-go-rewrite/rules"
+	"github.com/sryden-inc/gscan-go-rewrite/scan"
+)
+
+// Format selects an output sink for Render.
+type Format string
+
+const (
+	Text  Format = "text"
+	JSON  Format = "json"
+	SARIF Format = "sarif"
+)
+
+// Finding is the JSON/SARIF representation of a single rule match.
+type Finding struct {
+	Path     string `json:"path"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Line     int    `json:"line"`
+	Message  string `json:"message"`
+}
+
+// Volume is the scan result for a single volume.
+type Volume struct {
+	Volume    string             `json:"volume"`
+	Languages map[string]float64 `json:"languages"`
+	Findings  []Finding          `json:"findings"`
+}
+
+// Summary rolls findings up across every scanned volume.
+type Summary struct {
+	TotalFindings      int            `json:"total_findings"`
+	FindingsByRule     map[string]int `json:"findings_by_rule"`
+	FindingsBySeverity map[string]int `json:"findings_by_severity"`
+}
+
+// Report is the full result of a gscan run across one or more volumes.
+type Report struct {
+	Volumes []Volume `json:"volumes"`
+	Summary Summary  `json:"summary"`
+}
+
+// New builds a Report from the raw scan.Result for each volume.
+func New(volumePaths []string, results []scan.Result) Report {
+	r := Report{
+		Summary: Summary{
+			FindingsByRule:     map[string]int{},
+			FindingsBySeverity: map[string]int{},
+		},
+	}
+
+	for i, res := range results {
+		v := Volume{Volume: volumePaths[i], Languages: res.LanguagePercentages}
+		for path, findings := range res.Findings {
+			for _, f := range findings {
+				v.Findings = append(v.Findings, toFinding(path, f))
+				r.Summary.TotalFindings++
+				r.Summary.FindingsByRule[f.RuleID]++
+				r.Summary.FindingsBySeverity[f.Severity]++
+			}
+		}
+		r.Volumes = append(r.Volumes, v)
+	}
+
+	return r
+}
+
+func toFinding(path string, f rules.Finding) Finding {
+	return Finding{Path: path, Rule: f.RuleID, Severity: f.Severity, Line: f.Line, Message: f.Description}
+}
+
+// Render writes report to w in the given format.
+func Render(w io.Writer, format Format, report Report) error {
+	switch format {
+	case Text, "":
+		return renderText(w, report)
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case SARIF:
+		return renderSARIF(w, report)
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+func renderText(w io.Writer, report Report) error {
+	for _, v := range report.Volumes {
+		if len(v.Findings) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "Directory: %s\n\nLanguages:\n", v.Volume)
+		for language, percentage := range v.Languages {
+			fmt.Fprintf(w, "* %.0f%% %s\n", percentage, language)
+		}
+
+		fmt.Fprintln(w, "\nFlags found in files:")
+		byPath := groupByPath(v.Findings)
+		for path, findings := range byPath {
+			fmt.Fprintf(w, "%s:\n", path)
+			for _, f := range findings {
+				fmt.Fprintf(w, "- [%s] %s (line %d)\n", f.Rule, f.Message, f.Line)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	if report.Summary.TotalFindings == 0 {
+		fmt.Fprintln(w, "No flags found in any volume.")
+		return nil
+	}
+
+	fmt.Fprintln(w, "Summary of flags found in all volumes:")
+	for _, v := range report.Volumes {
+		byPath := groupByPath(v.Findings)
+		for path, findings := range byPath {
+			fmt.Fprintf(w, "%s:\n", path)
+			for _, f := range findings {
+				fmt.Fprintf(w, "- [%s] %s (line %d)\n", f.Rule, f.Message, f.Line)
+			}
+		}
+	}
+	return nil
+}
+
+func groupByPath(findings []Finding) map[string][]Finding {
+	byPath := make(map[string][]Finding)
+	for _, f := range findings {
+		byPath[f.Path] = append(byPath[f.Path], f)
+	}
+	return byPath
+}
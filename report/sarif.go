@@ -0,0 +1,142 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// SARIF 2.1.0 types. Only the subset gscan needs is modeled; see
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/ for the full schema.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+func renderSARIF(w io.Writer, report Report) error {
+	log := sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{Driver: sarifDriver{Name: "gscan", Rules: sarifRulesFrom(report)}},
+			},
+		},
+	}
+
+	for _, v := range report.Volumes {
+		for _, f := range v.Findings {
+			log.Runs[0].Results = append(log.Runs[0].Results, sarifResult{
+				RuleID:  f.Rule,
+				Level:   sarifLevel(f.Severity),
+				Message: sarifMessage{Text: f.Message},
+				Locations: []sarifLocation{
+					{
+						PhysicalLocation: sarifPhysicalLocation{
+							ArtifactLocation: sarifArtifactLocation{URI: f.Path},
+							Region:           sarifRegionFor(f.Line),
+						},
+					},
+				},
+			})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+func sarifRulesFrom(report Report) []sarifRule {
+	descriptions := make(map[string]string)
+	for _, v := range report.Volumes {
+		for _, f := range v.Findings {
+			descriptions[f.Rule] = f.Message
+		}
+	}
+
+	ids := make([]string, 0, len(descriptions))
+	for id := range descriptions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	rules := make([]sarifRule, 0, len(ids))
+	for _, id := range ids {
+		rules = append(rules, sarifRule{ID: id, ShortDescription: sarifMessage{Text: descriptions[id]}})
+	}
+	return rules
+}
+
+// sarifRegionFor returns a region pointing at line, or nil if line isn't a
+// valid 1-based SARIF line number (e.g. a finding reconstructed from a
+// cache entry that predates per-finding line tracking). SARIF 2.1.0
+// requires startLine >= 1, so omitting the region entirely is safer than
+// emitting a line gscan doesn't actually know.
+func sarifRegionFor(line int) *sarifRegion {
+	if line < 1 {
+		return nil
+	}
+	return &sarifRegion{StartLine: line}
+}
+
+func sarifLevel(severity string) string {
+	switch severity {
+	case "high":
+		return "error"
+	case "medium":
+		return "warning"
+	case "low":
+		return "note"
+	default:
+		return "warning"
+	}
+}
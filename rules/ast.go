@@ -0,0 +1,148 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// astMatcher searches for a literal pattern in a file's token stream with
+// comments stripped out first; string literals are kept and still match.
+// This keeps matchers like "argo" from firing on a code comment that
+// happens to mention the word in passing.
+type astMatcher struct {
+	lang    string
+	pattern string
+}
+
+func newASTMatcher(lang, pattern string) (compiledMatcher, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("ast matcher requires a pattern")
+	}
+	switch lang {
+	case "js", "python":
+	default:
+		return nil, fmt.Errorf("ast matcher: unsupported lang %q (want js or python)", lang)
+	}
+	return astMatcher{lang: lang, pattern: pattern}, nil
+}
+
+func (m astMatcher) find(content []byte) []hit {
+	var stripped string
+	switch m.lang {
+	case "js":
+		stripped = stripJSComments(string(content))
+	case "python":
+		stripped = stripPythonComments(string(content))
+	}
+
+	return findInLines([]byte(stripped), func(line string) (string, bool) {
+		if strings.Contains(line, m.pattern) {
+			return line, true
+		}
+		return "", false
+	})
+}
+
+// stripJSComments blanks out // line comments and /* */ block comments,
+// preserving line breaks so downstream line numbers stay accurate. It is a
+// lightweight tokenizer, not a full parser: it tracks string/template
+// literals well enough to avoid treating "//" inside a string as a comment.
+func stripJSComments(src string) string {
+	var out strings.Builder
+	runes := []rune(src)
+	n := len(runes)
+
+	for i := 0; i < n; i++ {
+		c := runes[i]
+
+		switch {
+		case c == '"' || c == '\'' || c == '`':
+			quote := c
+			out.WriteRune(c)
+			i++
+			for i < n && runes[i] != quote {
+				out.WriteRune(runes[i])
+				if runes[i] == '\\' && i+1 < n {
+					i++
+					out.WriteRune(runes[i])
+				}
+				i++
+			}
+			if i < n {
+				out.WriteRune(runes[i])
+			}
+		case c == '/' && i+1 < n && runes[i+1] == '/':
+			for i < n && runes[i] != '\n' {
+				i++
+			}
+			i--
+		case c == '/' && i+1 < n && runes[i+1] == '*':
+			i += 2
+			for i < n && !(runes[i] == '*' && i+1 < n && runes[i+1] == '/') {
+				if runes[i] == '\n' {
+					out.WriteRune('\n')
+				}
+				i++
+			}
+			i++
+		default:
+			out.WriteRune(c)
+		}
+	}
+
+	return out.String()
+}
+
+// stripPythonComments blanks out # comments, leaving triple- and
+// single-quoted strings alone other than skipping over them so a commented
+// "#" inside a string literal isn't mistaken for a comment start.
+func stripPythonComments(src string) string {
+	var out strings.Builder
+	runes := []rune(src)
+	n := len(runes)
+
+	for i := 0; i < n; i++ {
+		c := runes[i]
+
+		switch {
+		case c == '"' || c == '\'':
+			quote := c
+			triple := i+2 < n && runes[i+1] == quote && runes[i+2] == quote
+			if triple {
+				out.WriteString(string([]rune{quote, quote, quote}))
+				i += 3
+				for i+2 < n && !(runes[i] == quote && runes[i+1] == quote && runes[i+2] == quote) {
+					out.WriteRune(runes[i])
+					i++
+				}
+				if i+2 < n {
+					out.WriteString(string([]rune{quote, quote, quote}))
+					i += 2
+				} else {
+					i = n - 1
+				}
+			} else {
+				out.WriteRune(quote)
+				i++
+				for i < n && runes[i] != quote && runes[i] != '\n' {
+					out.WriteRune(runes[i])
+					i++
+				}
+				if i < n && runes[i] == quote {
+					out.WriteRune(quote)
+				} else if i < n {
+					i--
+				}
+			}
+		case c == '#':
+			for i < n && runes[i] != '\n' {
+				i++
+			}
+			i--
+		default:
+			out.WriteRune(c)
+		}
+	}
+
+	return out.String()
+}
@@ -0,0 +1,25 @@
+package rules
+
+import (
+	_ "embed"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed default_rules.yaml
+var defaultRuleSet []byte
+
+// NewDefaultScanner returns a Scanner pre-loaded with gscan's built-in
+// signature pack (the checks that used to be hard-coded in checkFlags).
+func NewDefaultScanner() (*Scanner, error) {
+	var set RuleSet
+	if err := yaml.Unmarshal(defaultRuleSet, &set); err != nil {
+		return nil, err
+	}
+
+	s := NewScanner()
+	if err := s.Load(set.Rules); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
@@ -0,0 +1,301 @@
+// Package rules implements a pluggable signature engine for gscan.
+//
+// A rule set is a flat list of rule definitions loaded from a YAML or JSON
+// file. Each rule can carry any number of matchers (literal substrings,
+// regular expressions, or language-aware AST predicates) and an optional
+// list of file globs restricting which paths it applies to. A Scanner
+// compiles a rule set once at startup and is then safe for concurrent use
+// by multiple scanning goroutines.
+package rules
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Finding is a single rule match against a file.
+type Finding struct {
+	RuleID      string `json:"rule_id"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+	Path        string `json:"path"`
+	Line        int    `json:"line"`
+	Snippet     string `json:"snippet"`
+}
+
+// Rule is a single compiled signature. Implementations are produced by the
+// Scanner from rule definitions; callers outside this package only see the
+// interface.
+type Rule interface {
+	ID() string
+	Severity() string
+	Description() string
+	FileGlobs() []string
+	Match(path string, content []byte) []Finding
+}
+
+// MatcherDef describes one matcher attached to a rule definition.
+type MatcherDef struct {
+	// Type is one of "literal", "regex", or "ast".
+	Type string `yaml:"type" json:"type"`
+	// Pattern is the literal substring or regular expression to search for.
+	// For Type "ast" it is matched as a literal substring against the token
+	// stream with comments stripped out; string contents are kept.
+	Pattern string `yaml:"pattern" json:"pattern"`
+	// Lang selects the tokenizer used for Type "ast": "js" or "python".
+	Lang string `yaml:"lang,omitempty" json:"lang,omitempty"`
+}
+
+// Definition is the on-disk shape of a rule, as loaded from YAML/JSON.
+type Definition struct {
+	ID          string       `yaml:"id" json:"id"`
+	Severity    string       `yaml:"severity" json:"severity"`
+	Description string       `yaml:"description" json:"description"`
+	FileGlobs   []string     `yaml:"file_globs" json:"file_globs"`
+	Matchers    []MatcherDef `yaml:"matchers" json:"matchers"`
+}
+
+// RuleSet is the top-level document shape: a list of rule definitions.
+type RuleSet struct {
+	Rules []Definition `yaml:"rules" json:"rules"`
+}
+
+// Scanner holds a compiled set of rules and dispatches matching for files
+// encountered during a scan.
+type Scanner struct {
+	rules []*compiledRule
+}
+
+// NewScanner returns an empty Scanner. Use Load or LoadFile to populate it.
+func NewScanner() *Scanner {
+	return &Scanner{}
+}
+
+// LoadFile reads a rule set from a YAML (.yaml/.yml) or JSON (.json) file
+// and appends its rules to the scanner.
+func (s *Scanner) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading rule file %s: %w", path, err)
+	}
+
+	var set RuleSet
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		if err := json.Unmarshal(data, &set); err != nil {
+			return fmt.Errorf("parsing rule file %s: %w", path, err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &set); err != nil {
+			return fmt.Errorf("parsing rule file %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("rule file %s: unrecognized extension %q (want .yaml, .yml or .json)", path, ext)
+	}
+
+	return s.Load(set.Rules)
+}
+
+// Load compiles and appends the given rule definitions to the scanner.
+func (s *Scanner) Load(defs []Definition) error {
+	for _, def := range defs {
+		cr, err := compile(def)
+		if err != nil {
+			return fmt.Errorf("rule %q: %w", def.ID, err)
+		}
+		s.rules = append(s.rules, cr)
+	}
+	return nil
+}
+
+// Rules returns the scanner's compiled rules.
+func (s *Scanner) Rules() []Rule {
+	out := make([]Rule, len(s.rules))
+	for i, r := range s.rules {
+		out[i] = r
+	}
+	return out
+}
+
+// Version returns a stable digest of the loaded rule set. Callers (notably
+// the scan cache) use it to invalidate cached results when the rule set
+// changes, since a cached "no findings" result is only valid for the rules
+// it was produced against.
+func (s *Scanner) Version() string {
+	h := sha256.New()
+	for _, r := range s.rules {
+		fmt.Fprintf(h, "%s\x00%s\x00%v\x00%v\x00", r.def.ID, r.def.Severity, r.def.FileGlobs, r.def.Matchers)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Describe returns the static severity and description for a rule ID, as
+// loaded from its definition. It's used to fill in those two fields when
+// reconstructing a Finding from a cached rule ID, line and snippet.
+func (s *Scanner) Describe(id string) (severity, description string, ok bool) {
+	for _, r := range s.rules {
+		if r.def.ID == id {
+			return r.def.Severity, r.def.Description, true
+		}
+	}
+	return "", "", false
+}
+
+// Match runs every applicable rule against content and returns the combined
+// findings. A rule only runs if path matches at least one of its file globs
+// (rules with no globs apply to every file).
+func (s *Scanner) Match(path string, content []byte) []Finding {
+	var findings []Finding
+	for _, r := range s.rules {
+		if !r.appliesTo(path) {
+			continue
+		}
+		findings = append(findings, r.Match(path, content)...)
+	}
+	return findings
+}
+
+type compiledRule struct {
+	def      Definition
+	globs    []string
+	matchers []compiledMatcher
+}
+
+func compile(def Definition) (*compiledRule, error) {
+	if def.ID == "" {
+		return nil, fmt.Errorf("missing id")
+	}
+
+	cr := &compiledRule{def: def, globs: def.FileGlobs}
+	for _, m := range def.Matchers {
+		cm, err := compileMatcher(m)
+		if err != nil {
+			return nil, err
+		}
+		cr.matchers = append(cr.matchers, cm)
+	}
+	return cr, nil
+}
+
+func (r *compiledRule) ID() string          { return r.def.ID }
+func (r *compiledRule) Severity() string    { return r.def.Severity }
+func (r *compiledRule) Description() string { return r.def.Description }
+func (r *compiledRule) FileGlobs() []string { return r.globs }
+
+func (r *compiledRule) appliesTo(path string) bool {
+	if len(r.globs) == 0 {
+		return true
+	}
+	base := filepath.Base(path)
+	for _, g := range r.globs {
+		if ok, _ := filepath.Match(g, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Match runs all of the rule's matchers against content. A rule with no
+// matchers at all matches unconditionally (useful for glob-only rules like
+// "flag every .sh file").
+func (r *compiledRule) Match(path string, content []byte) []Finding {
+	if len(r.matchers) == 0 {
+		return []Finding{r.finding(path, 1, "")}
+	}
+
+	var findings []Finding
+	for _, m := range r.matchers {
+		for _, hit := range m.find(content) {
+			findings = append(findings, r.finding(path, hit.line, hit.snippet))
+		}
+	}
+	return findings
+}
+
+func (r *compiledRule) finding(path string, line int, snippet string) Finding {
+	return Finding{
+		RuleID:      r.def.ID,
+		Severity:    r.def.Severity,
+		Description: r.def.Description,
+		Path:        path,
+		Line:        line,
+		Snippet:     snippet,
+	}
+}
+
+type hit struct {
+	line    int
+	snippet string
+}
+
+type compiledMatcher interface {
+	find(content []byte) []hit
+}
+
+func compileMatcher(def MatcherDef) (compiledMatcher, error) {
+	switch def.Type {
+	case "literal":
+		if def.Pattern == "" {
+			return nil, fmt.Errorf("literal matcher requires a pattern")
+		}
+		return literalMatcher{pattern: def.Pattern}, nil
+	case "regex":
+		re, err := regexp.Compile(def.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("compiling regex %q: %w", def.Pattern, err)
+		}
+		return regexMatcher{re: re}, nil
+	case "ast":
+		return newASTMatcher(def.Lang, def.Pattern)
+	default:
+		return nil, fmt.Errorf("unknown matcher type %q", def.Type)
+	}
+}
+
+type literalMatcher struct {
+	pattern string
+}
+
+func (m literalMatcher) find(content []byte) []hit {
+	return findInLines(content, func(line string) (string, bool) {
+		if idx := strings.Index(line, m.pattern); idx >= 0 {
+			return line, true
+		}
+		return "", false
+	})
+}
+
+type regexMatcher struct {
+	re *regexp.Regexp
+}
+
+func (m regexMatcher) find(content []byte) []hit {
+	return findInLines(content, func(line string) (string, bool) {
+		if loc := m.re.FindString(line); loc != "" {
+			return line, true
+		}
+		return "", false
+	})
+}
+
+// findInLines splits content into lines and applies check to each, tracking
+// 1-based line numbers so findings can point at a location worth showing a
+// reviewer.
+func findInLines(content []byte, check func(line string) (string, bool)) []hit {
+	var hits []hit
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		if snippet, ok := check(line); ok {
+			hits = append(hits, hit{line: i + 1, snippet: strings.TrimSpace(snippet)})
+		}
+	}
+	return hits
+}
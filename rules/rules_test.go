@@ -0,0 +1,123 @@
+package rules
+
+import "testing"
+
+func TestScannerMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		def     Definition
+		path    string
+		content string
+		want    int
+	}{
+		{
+			name:    "literal match",
+			def:     Definition{ID: "nezha", Matchers: []MatcherDef{{Type: "literal", Pattern: "nezha"}}},
+			path:    "agent.js",
+			content: "var x = \"nezha-agent\";",
+			want:    1,
+		},
+		{
+			name:    "literal no match",
+			def:     Definition{ID: "nezha", Matchers: []MatcherDef{{Type: "literal", Pattern: "nezha"}}},
+			path:    "agent.js",
+			content: "var x = 1;",
+			want:    0,
+		},
+		{
+			name:    "regex match",
+			def:     Definition{ID: "han", Matchers: []MatcherDef{{Type: "regex", Pattern: `\p{Han}`}}},
+			path:    "readme.txt",
+			content: "hello 世界",
+			want:    1,
+		},
+		{
+			name:    "glob-only rule matches unconditionally",
+			def:     Definition{ID: "shell", FileGlobs: []string{"*.sh"}},
+			path:    "install.sh",
+			content: "anything at all",
+			want:    1,
+		},
+		{
+			name:    "glob restricts applicability",
+			def:     Definition{ID: "shell", FileGlobs: []string{"*.sh"}},
+			path:    "install.py",
+			content: "anything at all",
+			want:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScanner()
+			if err := s.Load([]Definition{tt.def}); err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+
+			got := s.Match(tt.path, []byte(tt.content))
+			if len(got) != tt.want {
+				t.Fatalf("Match() returned %d findings, want %d: %+v", len(got), tt.want, got)
+			}
+		})
+	}
+}
+
+func TestScannerMatchASTIgnoresComments(t *testing.T) {
+	s := NewScanner()
+	def := Definition{ID: "argo", Matchers: []MatcherDef{{Type: "ast", Lang: "js", Pattern: "argo"}}}
+	if err := s.Load([]Definition{def}); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	// "argo" only appears inside a comment: the ast matcher must not flag it.
+	findings := s.Match("index.js", []byte("// argo tunnel reference\nconst x = 1;"))
+	if len(findings) != 0 {
+		t.Fatalf("expected no findings for a comment-only match, got %+v", findings)
+	}
+
+	// "argo" appears in a real string literal: the ast matcher must flag it.
+	findings = s.Match("index.js", []byte("const tunnel = \"argo-tunnel\";"))
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding for a string-literal match, got %+v", findings)
+	}
+}
+
+func TestStripJSCommentsPreservesStringContents(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "line comment stripped, string kept",
+			in:   "var x = \"nezha-agent\"; // installs the agent",
+			want: "var x = \"nezha-agent\"; ",
+		},
+		{
+			name: "block comment stripped, string kept",
+			in:   "var x = /* set up */ \"argo\";",
+			want: "var x =  \"argo\";",
+		},
+		{
+			name: "escaped quote inside string survives",
+			in:   "var x = \"a\\\"b\"; // c",
+			want: "var x = \"a\\\"b\"; ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripJSComments(tt.in); got != tt.want {
+				t.Errorf("stripJSComments(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripPythonCommentsPreservesStringContents(t *testing.T) {
+	in := "x = \"nezha-agent\"  # installs the agent"
+	want := "x = \"nezha-agent\"  "
+	if got := stripPythonComments(in); got != want {
+		t.Errorf("stripPythonComments(%q) = %q, want %q", in, got, want)
+	}
+}
@@ -0,0 +1,352 @@
+// Package scan walks a directory tree once, classifying and rule-matching
+// every file it finds. Work fans out to a bounded pool of workers so a scan
+// of a large volume is limited by disk/CPU throughput rather than by doing
+// everything on a single goroutine.
+package scan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/sryden-inc/gscan-go-rewrite/archive"
+	"github.com/sryden-inc/gscan-go-rewrite/cache"
+	"github.com/sryden-inc/gscan-go-rewrite/langid"
+	"github.com/sryden-inc/gscan-go-rewrite/rules"
+)
+
+// MaxFileSize is the largest file content gscan will read into memory for
+// classification and rule matching. Larger files are skipped.
+const MaxFileSize = 1024 * 1024 * 10 // 10 MB
+
+// Result is the aggregated outcome of walking a directory tree.
+type Result struct {
+	LanguagePercentages map[string]float64
+	Findings            map[string][]rules.Finding
+}
+
+// Options controls how a Walk behaves.
+type Options struct {
+	// Scanner matches rule-flagged content in each file. Required.
+	Scanner *rules.Scanner
+	// IncludeVendored, when true, walks into vendored directories
+	// (node_modules, plugins, assets, ...) instead of skipping them
+	// outright, and counts their files toward the language percentages.
+	IncludeVendored bool
+	// Workers is the number of files read and scanned concurrently. Zero
+	// means runtime.NumCPU().
+	Workers int
+	// ArchiveAllocator bounds archive extraction (zip/tar.gz/rar/jar). Nil
+	// means archive.NewTempDirAllocator().
+	ArchiveAllocator *archive.TempDirAllocator
+	// Cache, if set, is consulted before opening each file and updated with
+	// fresh results after scanning it. Nil disables caching.
+	Cache *cache.Cache
+}
+
+// Walk traverses root once with filepath.WalkDir, fanning file reads and
+// rule matching out to a bounded worker pool, and returns the aggregated
+// language percentages and findings for the whole tree.
+func Walk(root string, opts Options) (Result, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	alloc := opts.ArchiveAllocator
+	if alloc == nil {
+		alloc = archive.NewTempDirAllocator()
+	}
+
+	var rulesVersion string
+	if opts.Cache != nil {
+		rulesVersion = opts.Scanner.Version()
+	}
+
+	paths := make(chan walkEntry)
+	results := make(chan fileResult)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for we := range paths {
+				for _, res := range scanPath(we, opts.Scanner, alloc, opts.Cache, rulesVersion) {
+					results <- res
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var walkErr error
+	go func() {
+		defer close(paths)
+		walkErr = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			// Ignore directories that start with "." or "?"
+			if d.IsDir() && (strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "?")) {
+				return filepath.SkipDir
+			}
+
+			// Skip vendored directories (node_modules, plugins, assets, ...)
+			// outright, as the original walker did, unless the caller opted
+			// into scanning them with -include-vendored.
+			if d.IsDir() && langid.IsVendoredDir(d.Name()) && !opts.IncludeVendored {
+				return filepath.SkipDir
+			}
+
+			if !d.IsDir() {
+				info, err := d.Info()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error stating file %s: %v\n", path, err)
+					return nil
+				}
+				paths <- walkEntry{path: path, modTime: info.ModTime().Unix(), size: info.Size()}
+			}
+			return nil
+		})
+	}()
+
+	languageCounts := make(map[string]int)
+	totalFiles := 0
+	findings := make(map[string][]rules.Finding)
+
+	for res := range results {
+		if res.err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading file %s: %v\n", res.path, res.err)
+			continue
+		}
+
+		if opts.IncludeVendored || !res.class.Vendored {
+			languageCounts[res.class.Language]++
+			totalFiles++
+		}
+
+		if len(res.findings) > 0 {
+			findings[res.path] = res.findings
+		}
+	}
+
+	if walkErr != nil {
+		return Result{}, fmt.Errorf("walking %s: %w", root, walkErr)
+	}
+
+	languagePercentages := make(map[string]float64)
+	for lang, count := range languageCounts {
+		languagePercentages[lang] = float64(count) / float64(totalFiles) * 100.0
+	}
+
+	return Result{LanguagePercentages: languagePercentages, Findings: findings}, nil
+}
+
+// walkEntry is a file discovered by the walker, carrying the mtime/size the
+// walker read from its fs.DirEntry so the cache's pre-read check in the
+// worker doesn't need a second stat.
+type walkEntry struct {
+	path    string
+	modTime int64
+	size    int64
+}
+
+type fileResult struct {
+	path     string
+	class    langid.Classification
+	findings []rules.Finding
+	err      error
+}
+
+// scanPath classifies and rule-matches path, and — if it recognizes path as
+// an archive — extracts it under a bounded TempDirAllocator and scans each
+// entry too, reporting entries under a synthetic "archive!/entry" path.
+// Rule matching is skipped for content langid classifies as Binary (an
+// archive's own bytes, images, etc.) so containers don't pick up spurious
+// findings from their compressed payload; their extracted entries are
+// still classified and matched individually.
+//
+// Archive contents are always extracted and rescanned, even when the outer
+// file itself hits the cache: the cache only remembers the container's own
+// findings, not what's inside it, so skipping extraction on a cache hit
+// would make anything an archive carries invisible after the first scan.
+func scanPath(we walkEntry, scanner *rules.Scanner, alloc *archive.TempDirAllocator, c *cache.Cache, rulesVersion string) []fileResult {
+	path := we.path
+
+	if c != nil {
+		if entry, ok := c.LookupStat(path, we.modTime, we.size, rulesVersion); ok {
+			results := []fileResult{resultFromCacheEntry(path, entry, scanner)}
+			return append(results, scanArchiveEntries(path, nil, scanner, alloc)...)
+		}
+	}
+
+	content, err := readLimited(path, MaxFileSize)
+	if err != nil {
+		return []fileResult{{path: path, err: err}}
+	}
+
+	class := langid.Detect(path, content)
+
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	var findings []rules.Finding
+	reused := false
+	if c != nil {
+		if entry, ok := c.LookupHash(path, hash, rulesVersion); ok {
+			// Content is unchanged from the last scan (e.g. a no-op touch);
+			// reuse the cached findings instead of re-running every rule.
+			findings = findingsFromCache(path, entry.Findings, scanner)
+			reused = true
+		}
+	}
+	if !reused && class.Language != langid.Binary {
+		findings = scanner.Match(path, content)
+	}
+
+	if c != nil {
+		c.Store(cache.Entry{
+			Path:         path,
+			ModTime:      we.modTime,
+			Size:         we.size,
+			SHA256:       hash,
+			RulesVersion: rulesVersion,
+			Findings:     cachedFindingsOf(findings),
+			Language:     class.Language,
+		})
+	}
+
+	results := []fileResult{{path: path, class: class, findings: findings}}
+	return append(results, scanArchiveEntries(path, content, scanner, alloc)...)
+}
+
+// scanArchiveEntries detects whether path is a recognized archive and, if
+// so, extracts and scans every entry inside it. content is the file's
+// already-read bytes when available (used for magic-byte sniffing); it may
+// be nil, in which case detection falls back to path's extension alone.
+func scanArchiveEntries(path string, content []byte, scanner *rules.Scanner, alloc *archive.TempDirAllocator) []fileResult {
+	header := content
+	if len(header) > 8 {
+		header = header[:8]
+	}
+	format := archive.DetectFormat(path, header)
+	if format == archive.Unknown {
+		return nil
+	}
+
+	entries, cleanup, err := archive.Extract(path, format, alloc)
+	defer cleanup()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error extracting archive %s: %v\n", path, err)
+		return nil
+	}
+
+	var results []fileResult
+	for _, entry := range entries {
+		entryContent, err := readLimited(entry.ExtractedPath, MaxFileSize)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading archive entry %s: %v\n", entry.Path, err)
+			continue
+		}
+		entryClass := langid.Detect(entry.Path, entryContent)
+		var entryFindings []rules.Finding
+		if entryClass.Language != langid.Binary {
+			entryFindings = scanner.Match(entry.Path, entryContent)
+		}
+		results = append(results, fileResult{
+			path:     entry.Path,
+			class:    entryClass,
+			findings: entryFindings,
+		})
+	}
+
+	return results
+}
+
+// readLimited reads up to limit+1 bytes of path without ever calling Stat,
+// so an oversize file short-circuits after reading one byte past the limit
+// instead of being fully allocated first.
+func readLimited(path string, limit int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(content)) > limit {
+		return nil, fmt.Errorf("file %s is too large (limit: %d bytes)", path, limit)
+	}
+
+	return content, nil
+}
+
+// resultFromCacheEntry rebuilds a fileResult from a cache hit, without ever
+// having opened the file.
+func resultFromCacheEntry(path string, entry cache.Entry, scanner *rules.Scanner) fileResult {
+	return fileResult{
+		path: path,
+		class: langid.Classification{
+			Language:      entry.Language,
+			Vendored:      langid.IsVendoredPath(path),
+			Documentation: langid.IsDocumentationPath(path),
+			Configuration: langid.IsConfigurationPath(path),
+		},
+		findings: findingsFromCache(path, entry.Findings, scanner),
+	}
+}
+
+// findingsFromCache reconstructs Finding values from a cache entry's
+// per-occurrence rule ID, line, and snippet. Severity and description are
+// looked up fresh from the scanner, which is safe since a cache hit already
+// guarantees the rules version matches.
+func findingsFromCache(path string, cached []cache.CachedFinding, scanner *rules.Scanner) []rules.Finding {
+	if len(cached) == 0 {
+		return nil
+	}
+
+	findings := make([]rules.Finding, 0, len(cached))
+	for _, cf := range cached {
+		severity, description, ok := scanner.Describe(cf.RuleID)
+		if !ok {
+			continue
+		}
+		findings = append(findings, rules.Finding{
+			RuleID:      cf.RuleID,
+			Severity:    severity,
+			Description: description,
+			Path:        path,
+			Line:        cf.Line,
+			Snippet:     cf.Snippet,
+		})
+	}
+	return findings
+}
+
+func cachedFindingsOf(findings []rules.Finding) []cache.CachedFinding {
+	if len(findings) == 0 {
+		return nil
+	}
+	cached := make([]cache.CachedFinding, len(findings))
+	for i, f := range findings {
+		cached[i] = cache.CachedFinding{RuleID: f.RuleID, Line: f.Line, Snippet: f.Snippet}
+	}
+	return cached
+}